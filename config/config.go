@@ -0,0 +1,155 @@
+// Package config loads dockdash's on-disk settings: key bindings, color
+// theme, and startup toggles. It knows nothing about termui or the
+// connector/view packages so it can be imported from anywhere without
+// pulling in the UI stack.
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// KeyBindings maps each user action to the key that triggers it. Single
+// character actions are given as one-rune strings; the arrow keys are
+// given as the named constants "Up", "Down", "Left", "Right".
+type KeyBindings struct {
+	Quit          string `toml:"quit"`
+	ToggleInspect string `toml:"toggle_inspect"`
+	CycleSort     string `toml:"cycle_sort"`
+	ToggleShowAll string `toml:"toggle_show_all"`
+	Filter        string `toml:"filter"`
+	Start         string `toml:"start"`
+	Stop          string `toml:"stop"`
+	Restart       string `toml:"restart"`
+	Kill          string `toml:"kill"`
+	Pause         string `toml:"pause"`
+	Remove        string `toml:"remove"`
+	MoveUp        string `toml:"move_up"`
+	MoveDown      string `toml:"move_down"`
+	MoveLeft      string `toml:"move_left"`
+	MoveRight     string `toml:"move_right"`
+}
+
+// Colors is the set of named colors used across the container list,
+// sparklines, and overlays. Valid names are the eight ANSI color
+// names: black, red, green, yellow, blue, magenta, cyan, white.
+type Colors struct {
+	Border    string `toml:"border"`
+	Header    string `toml:"header"`
+	Selection string `toml:"selection"`
+	CpuChart  string `toml:"cpu_chart"`
+	MemChart  string `toml:"mem_chart"`
+}
+
+// Toggles are the boolean startup switches a user might want to flip
+// without passing flags every time.
+type Toggles struct {
+	StartInInspectMode    bool   `toml:"start_in_inspect_mode"`
+	ShowStoppedContainers bool   `toml:"show_stopped_containers"`
+	SortMode              string `toml:"sort_mode"`
+}
+
+// Config is the full contents of config.toml.
+type Config struct {
+	Keys    KeyBindings `toml:"keys"`
+	Colors  Colors      `toml:"colors"`
+	Toggles Toggles     `toml:"toggles"`
+}
+
+// Default returns the settings dockdash used before config.toml existed,
+// so an un-configured install behaves exactly as before.
+func Default() *Config {
+	return &Config{
+		Keys: KeyBindings{
+			Quit:          "q",
+			ToggleInspect: "i",
+			CycleSort:     "o",
+			ToggleShowAll: "a",
+			Filter:        "/",
+			Start:         "s",
+			Stop:          "S",
+			Restart:       "r",
+			Kill:          "k",
+			Pause:         "p",
+			Remove:        "x",
+			MoveUp:        "Up",
+			MoveDown:      "Down",
+			MoveLeft:      "Left",
+			MoveRight:     "Right",
+		},
+		Colors: Colors{
+			Border:    "cyan",
+			Header:    "white",
+			Selection: "yellow",
+			CpuChart:  "green",
+			MemChart:  "magenta",
+		},
+		Toggles: Toggles{
+			StartInInspectMode:    false,
+			ShowStoppedContainers: false,
+			SortMode:              "name",
+		},
+	}
+}
+
+// Path resolves the on-disk location of config.toml: $XDG_CONFIG_HOME/
+// dockdash/config.toml, falling back to ~/.config/dockdash/config.toml.
+func Path() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "dockdash", "config.toml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "dockdash", "config.toml"), nil
+}
+
+// Load reads config.toml from path (or the default location when path is
+// empty) and overlays it on top of Default. A missing file is not an
+// error: it just yields the defaults.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		var err error
+		path, err = Path()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := Default()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if _, err := toml.DecodeFile(path, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// WriteDefault dumps the effective defaults to path (or the default
+// location when path is empty), creating parent directories as needed.
+// It's used by the --write-config flag so users have a starting point
+// to edit.
+func WriteDefault(path string) error {
+	if path == "" {
+		var err error
+		path, err = Path()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return toml.NewEncoder(file).Encode(Default())
+}
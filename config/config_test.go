@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadMissingFileReturnsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Fatalf("expected defaults for a missing file, got %+v", cfg)
+	}
+}
+
+func TestLoadOverlaysFileOnDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	contents := "[keys]\nquit = \"Q\"\n\n[toggles]\nshow_stopped_containers = true\n"
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write test config: %s", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+	if cfg.Keys.Quit != "Q" {
+		t.Fatalf("expected overridden quit key, got %q", cfg.Keys.Quit)
+	}
+	if !cfg.Toggles.ShowStoppedContainers {
+		t.Fatal("expected overridden show_stopped_containers")
+	}
+	if cfg.Keys.MoveUp != Default().Keys.MoveUp {
+		t.Fatalf("expected unset fields to keep default, got %q", cfg.Keys.MoveUp)
+	}
+}
+
+func TestWriteDefaultRoundTrips(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	if err := WriteDefault(path); err != nil {
+		t.Fatalf("WriteDefault returned error: %s", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Fatalf("expected round-tripped config to match defaults, got %+v", cfg)
+	}
+}
@@ -0,0 +1,110 @@
+// Package connector abstracts the container runtime dockdash talks to,
+// so the UI and docklistener packages don't need to know whether they're
+// pointed at a Docker daemon or a bare containerd socket.
+package connector
+
+import (
+	"fmt"
+	"io"
+)
+
+// Action is a lifecycle operation that can be performed on a container.
+type Action string
+
+const (
+	ActionStart   Action = "start"
+	ActionStop    Action = "stop"
+	ActionRestart Action = "restart"
+	ActionKill    Action = "kill"
+	ActionPause   Action = "pause"
+	ActionRemove  Action = "remove"
+)
+
+// Container is a backend-agnostic snapshot of a single container. Raw
+// holds the backend's own inspect payload as JSON, for detail views that
+// want to show everything rather than just these common fields.
+type Container struct {
+	ID      string
+	Name    string
+	Image   string
+	Command string
+	Created string
+	Status  string
+	State   string
+	Ports   string
+	Raw     []byte
+}
+
+// EventType is a normalized container lifecycle transition.
+type EventType string
+
+const (
+	EventStart EventType = "start"
+	EventDie   EventType = "die"
+)
+
+// Event is a single container lifecycle transition reported by the
+// backend's event stream.
+type Event struct {
+	Type EventType
+	ID   string
+}
+
+// StatsSample is one point-in-time resource usage reading for a single
+// container.
+type StatsSample struct {
+	ContainerID string
+	CpuPercent  int
+	MemPercent  int
+	NetBytes    uint64
+	BlockBytes  uint64
+}
+
+// Connector is everything dockdash needs from a container runtime: list
+// containers, watch lifecycle events, sample stats, fetch logs, and
+// drive container actions.
+type Connector interface {
+	// List returns the currently known containers. When all is false,
+	// only running containers are returned.
+	List(all bool) ([]*Container, error)
+
+	// Inspect returns the full current state of a single container.
+	Inspect(id string) (*Container, error)
+
+	// Events streams container lifecycle transitions until the
+	// returned channel is abandoned by the caller.
+	Events() (<-chan Event, error)
+
+	// Stats streams resource usage samples for a single container. When
+	// stream is false, at most one sample is sent before the channel is
+	// closed (none if sampling fails). When stream is true, closing stop ends the stream and
+	// releases whatever connection/goroutines were sampling it; callers
+	// doing a one-shot (stream false) read may pass a nil stop.
+	Stats(id string, stream bool, stop <-chan struct{}) (<-chan *StatsSample, error)
+
+	// Logs opens the log stream for a single container. When follow is
+	// true the returned reader keeps delivering new output until closed.
+	// Closing stop tears down the stream immediately, the same way it
+	// does for Stats; a one-shot (follow false) caller may pass nil.
+	Logs(id string, follow bool, tail string, stop <-chan struct{}) (io.ReadCloser, error)
+
+	// Do performs a lifecycle action on a container.
+	Do(id string, action Action) error
+
+	// Close releases any resources held by the connector (sockets,
+	// clients, etc).
+	Close() error
+}
+
+// New constructs the connector for the named backend ("docker" or
+// "containerd") pointed at endpoint.
+func New(backend, endpoint string) (Connector, error) {
+	switch backend {
+	case "", "docker":
+		return NewDockerConnector(endpoint)
+	case "containerd":
+		return NewContainerdConnector(endpoint, "")
+	default:
+		return nil, fmt.Errorf("unknown backend %q (want \"docker\" or \"containerd\")", backend)
+	}
+}
@@ -0,0 +1,324 @@
+package connector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containerd/containerd"
+	eventstypes "github.com/containerd/containerd/api/events"
+	"github.com/containerd/containerd/cio"
+	containerdevents "github.com/containerd/containerd/events"
+	v1 "github.com/containerd/containerd/metrics/types/v1"
+	"github.com/containerd/containerd/namespaces"
+	"github.com/containerd/typeurl"
+)
+
+const defaultNamespace = "default"
+
+// ContainerdConnector talks directly to a containerd daemon, for hosts
+// (k3s, bare containerd) that don't run a Docker daemon at all.
+type ContainerdConnector struct {
+	client    *containerd.Client
+	namespace string
+}
+
+// NewContainerdConnector dials containerd at endpoint (e.g.
+// "/run/containerd/containerd.sock") in the given namespace, defaulting
+// to "default".
+func NewContainerdConnector(endpoint, namespace string) (*ContainerdConnector, error) {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	client, err := containerd.New(endpoint, containerd.WithDefaultNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	return &ContainerdConnector{client: client, namespace: namespace}, nil
+}
+
+func (c *ContainerdConnector) ctx() context.Context {
+	return namespaces.WithNamespace(context.Background(), c.namespace)
+}
+
+func (c *ContainerdConnector) List(all bool) ([]*Container, error) {
+	containers, err := c.client.Containers(c.ctx())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Container, 0, len(containers))
+	for _, cont := range containers {
+		conv, err := c.toContainer(cont)
+		if err != nil {
+			continue
+		}
+		if !all && conv.State != string(containerd.Running) {
+			continue
+		}
+		out = append(out, conv)
+	}
+	return out, nil
+}
+
+func (c *ContainerdConnector) Inspect(id string) (*Container, error) {
+	cont, err := c.client.LoadContainer(c.ctx(), id)
+	if err != nil {
+		return nil, err
+	}
+	return c.toContainer(cont)
+}
+
+func (c *ContainerdConnector) toContainer(cont containerd.Container) (*Container, error) {
+	ctx := c.ctx()
+
+	info, err := cont.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := "unknown"
+	if task, err := cont.Task(ctx, nil); err == nil {
+		if status, err := task.Status(ctx); err == nil {
+			state = string(status.Status)
+		}
+	}
+
+	raw, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		raw = []byte(fmt.Sprintf("failed to marshal inspect data: %s", err))
+	}
+
+	return &Container{
+		ID:      cont.ID(),
+		Name:    cont.ID(),
+		Image:   info.Image,
+		Command: "",
+		Created: info.CreatedAt.String(),
+		Status:  state,
+		State:   state,
+		Ports:   "",
+		Raw:     raw,
+	}, nil
+}
+
+// Events subscribes to containerd's task lifecycle topics and normalizes
+// them into start/die transitions.
+func (c *ContainerdConnector) Events() (<-chan Event, error) {
+	envelopes, errs := c.client.EventService().Subscribe(c.ctx(), `topic~="^/tasks/"`)
+	eventChan := make(chan Event)
+	go func() {
+		defer close(eventChan)
+		for {
+			select {
+			case envelope, ok := <-envelopes:
+				if !ok {
+					return
+				}
+				id, ok := taskEventContainerID(envelope)
+				if !ok {
+					continue
+				}
+				switch {
+				case strings.HasPrefix(envelope.Topic, "/tasks/start"):
+					eventChan <- Event{Type: EventStart, ID: id}
+				case strings.HasPrefix(envelope.Topic, "/tasks/exit"), strings.HasPrefix(envelope.Topic, "/tasks/delete"):
+					eventChan <- Event{Type: EventDie, ID: id}
+				}
+			case <-errs:
+				return
+			}
+		}
+	}()
+	return eventChan, nil
+}
+
+// taskEventContainerID unmarshals a task lifecycle envelope's
+// typeurl-encoded payload to recover the container ID it refers to.
+// envelope.Namespace is the containerd namespace (e.g. "default"), not
+// a container ID, and must not be used as one.
+func taskEventContainerID(envelope *containerdevents.Envelope) (string, bool) {
+	payload, err := typeurl.UnmarshalAny(envelope.Event)
+	if err != nil {
+		return "", false
+	}
+	switch e := payload.(type) {
+	case *eventstypes.TaskStart:
+		return e.ContainerID, true
+	case *eventstypes.TaskExit:
+		return e.ContainerID, true
+	case *eventstypes.TaskDelete:
+		return e.ContainerID, true
+	default:
+		return "", false
+	}
+}
+
+// Stats samples cgroup metrics via the containerd task's Metrics call.
+// When stream is true it keeps sampling once a second until stop is
+// closed (or, for a one-shot caller, until the single sample is sent).
+func (c *ContainerdConnector) Stats(id string, stream bool, stop <-chan struct{}) (<-chan *StatsSample, error) {
+	sampleChan := make(chan *StatsSample)
+	go func() {
+		defer close(sampleChan)
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		for {
+			sample, err := c.sampleOnce(id)
+			if err == nil {
+				select {
+				case sampleChan <- sample:
+				case <-stop:
+					return
+				}
+			}
+			if !stream {
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return sampleChan, nil
+}
+
+func (c *ContainerdConnector) sampleOnce(id string) (*StatsSample, error) {
+	ctx := c.ctx()
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := cont.Task(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	metric, err := task.Metrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := typeurl.UnmarshalAny(metric.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := &StatsSample{ContainerID: id}
+	if m, ok := data.(*v1.Metrics); ok && m.CPU != nil && m.Memory != nil {
+		if m.CPU.Usage != nil {
+			sample.CpuPercent = int(m.CPU.Usage.Total / 1e9)
+		}
+		if m.Memory.Usage != nil && m.Memory.Usage.Limit > 0 {
+			sample.MemPercent = int(m.Memory.Usage.Usage * 100 / m.Memory.Usage.Limit)
+		}
+	}
+	return sample, nil
+}
+
+// Logs tails the container's log file on disk. Unlike the Docker API,
+// plain containerd has no daemon-side log ring buffer to call into;
+// this relies on the CRI-style log path recorded in the container's
+// labels, which is where most containerd setups (k3s, crictl) actually
+// write stdout/stderr.
+func (c *ContainerdConnector) Logs(id string, follow bool, tail string, stop <-chan struct{}) (io.ReadCloser, error) {
+	ctx := c.ctx()
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := cont.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logPath := labels["io.kubernetes.cri.log-directory"]
+	if logPath == "" {
+		return nil, fmt.Errorf("no log path recorded for container %s", id)
+	}
+
+	file, err := os.Open(logPath)
+	if err != nil {
+		return nil, err
+	}
+	if !follow {
+		return file, nil
+	}
+	return newFollowReader(file, stop), nil
+}
+
+// followReader re-reads from a file as it grows, approximating `tail -f`
+// for containerd's on-disk CRI logs, until stop is closed.
+type followReader struct {
+	file   *os.File
+	reader *bufio.Reader
+	stop   <-chan struct{}
+}
+
+func newFollowReader(file *os.File, stop <-chan struct{}) *followReader {
+	return &followReader{file: file, reader: bufio.NewReader(file), stop: stop}
+}
+
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.reader.Read(p)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		select {
+		case <-f.stop:
+			return 0, io.EOF
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (f *followReader) Close() error {
+	return f.file.Close()
+}
+
+func (c *ContainerdConnector) Do(id string, action Action) error {
+	ctx := c.ctx()
+	cont, err := c.client.LoadContainer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if action == ActionRemove {
+		return cont.Delete(ctx, containerd.WithSnapshotCleanup)
+	}
+
+	task, err := cont.Task(ctx, cio.Load)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case ActionStart:
+		return task.Start(ctx)
+	case ActionStop:
+		return task.Kill(ctx, syscall.SIGTERM)
+	case ActionKill:
+		return task.Kill(ctx, syscall.SIGKILL)
+	case ActionRestart:
+		if err := task.Kill(ctx, syscall.SIGTERM); err != nil {
+			return err
+		}
+		return task.Start(ctx)
+	case ActionPause:
+		return task.Pause(ctx)
+	default:
+		return fmt.Errorf("unsupported action %q", action)
+	}
+}
+
+func (c *ContainerdConnector) Close() error {
+	return c.client.Close()
+}
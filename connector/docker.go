@@ -0,0 +1,198 @@
+package connector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	goDocker "github.com/fsouza/go-dockerclient"
+)
+
+// DockerConnector talks to a Docker daemon over its API socket via
+// go-dockerclient. It's the original, and still default, backend.
+type DockerConnector struct {
+	client *goDocker.Client
+}
+
+// NewDockerConnector dials the Docker daemon at endpoint (e.g.
+// "unix:/var/run/docker.sock" or a tcp:// address).
+func NewDockerConnector(endpoint string) (*DockerConnector, error) {
+	client, err := goDocker.NewClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &DockerConnector{client: client}, nil
+}
+
+func (d *DockerConnector) List(all bool) ([]*Container, error) {
+	apiContainers, err := d.client.ListContainers(goDocker.ListContainersOptions{All: all})
+	if err != nil {
+		return nil, err
+	}
+	containers := make([]*Container, 0, len(apiContainers))
+	for _, apiContainer := range apiContainers {
+		cont, err := d.Inspect(apiContainer.ID)
+		if err != nil {
+			continue
+		}
+		containers = append(containers, cont)
+	}
+	return containers, nil
+}
+
+func (d *DockerConnector) Inspect(id string) (*Container, error) {
+	cont, err := d.client.InspectContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.MarshalIndent(cont, "", "  ")
+	if err != nil {
+		raw = []byte(fmt.Sprintf("failed to marshal inspect data: %s", err))
+	}
+	name := cont.Name
+	if len(name) > 0 && name[0] == '/' {
+		name = name[1:]
+	}
+	return &Container{
+		ID:      cont.ID,
+		Name:    name,
+		Image:   cont.Config.Image,
+		Command: cont.Path,
+		Created: cont.Created.String(),
+		Status:  cont.State.StateString(),
+		State:   cont.State.StateString(),
+		Ports:   fmt.Sprint(cont.NetworkSettings.Ports),
+		Raw:     raw,
+	}, nil
+}
+
+func (d *DockerConnector) Events() (<-chan Event, error) {
+	apiEventChan := make(chan *goDocker.APIEvents)
+	if err := d.client.AddEventListener(apiEventChan); err != nil {
+		return nil, err
+	}
+	eventChan := make(chan Event)
+	go func() {
+		defer close(eventChan)
+		for apiEvent := range apiEventChan {
+			switch apiEvent.Status {
+			case "start", "unpause":
+				eventChan <- Event{Type: EventStart, ID: apiEvent.ID}
+			case "die", "stop", "kill", "pause", "destroy":
+				eventChan <- Event{Type: EventDie, ID: apiEvent.ID}
+			}
+		}
+	}()
+	return eventChan, nil
+}
+
+func (d *DockerConnector) Stats(id string, stream bool, stop <-chan struct{}) (<-chan *StatsSample, error) {
+	dockerStatsChan := make(chan *goDocker.Stats)
+	done := make(chan bool)
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- d.client.Stats(goDocker.StatsOptions{
+			ID:     id,
+			Stats:  dockerStatsChan,
+			Stream: stream,
+			Done:   done,
+		})
+	}()
+
+	if stop != nil {
+		go func() {
+			<-stop
+			close(done)
+		}()
+	}
+
+	sampleChan := make(chan *StatsSample)
+	go func() {
+		defer close(sampleChan)
+		for stats := range dockerStatsChan {
+			select {
+			case sampleChan <- toStatsSample(id, stats):
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return sampleChan, nil
+}
+
+func (d *DockerConnector) Logs(id string, follow bool, tail string, stop <-chan struct{}) (io.ReadCloser, error) {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		err := d.client.Logs(goDocker.LogsOptions{
+			Container:    id,
+			OutputStream: pipeWriter,
+			ErrorStream:  pipeWriter,
+			Stdout:       true,
+			Stderr:       true,
+			Follow:       follow,
+			Tail:         tail,
+		})
+		pipeWriter.CloseWithError(err)
+	}()
+
+	if stop != nil {
+		go func() {
+			<-stop
+			// Closing the read end unblocks any in-flight Read and makes
+			// the daemon-side write above fail, which is what actually
+			// ends d.client.Logs and its goroutine - there's no separate
+			// cancellation knob on it.
+			pipeReader.Close()
+		}()
+	}
+
+	return pipeReader, nil
+}
+
+func (d *DockerConnector) Do(id string, action Action) error {
+	switch action {
+	case ActionStart:
+		return d.client.StartContainer(id, nil)
+	case ActionStop:
+		return d.client.StopContainer(id, 10)
+	case ActionRestart:
+		return d.client.RestartContainer(id, 10)
+	case ActionKill:
+		return d.client.KillContainer(goDocker.KillContainerOptions{ID: id})
+	case ActionPause:
+		return d.client.PauseContainer(id)
+	case ActionRemove:
+		return d.client.RemoveContainer(goDocker.RemoveContainerOptions{ID: id, Force: true})
+	default:
+		return fmt.Errorf("unsupported action %q", action)
+	}
+}
+
+func (d *DockerConnector) Close() error {
+	return nil
+}
+
+func toStatsSample(id string, stats *goDocker.Stats) *StatsSample {
+	sample := &StatsSample{ContainerID: id}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemCPUUsage) - float64(stats.PreCPUStats.SystemCPUUsage)
+	if systemDelta > 0 && cpuDelta > 0 {
+		sample.CpuPercent = int((cpuDelta / systemDelta) * float64(len(stats.CPUStats.CPUUsage.PercpuUsage)) * 100)
+	}
+
+	if stats.MemoryStats.Limit > 0 {
+		sample.MemPercent = int(float64(stats.MemoryStats.Usage) / float64(stats.MemoryStats.Limit) * 100)
+	}
+
+	for _, netStats := range stats.Networks {
+		sample.NetBytes += netStats.RxBytes + netStats.TxBytes
+	}
+
+	for _, entry := range stats.BlkioStats.IOServiceBytesRecursive {
+		sample.BlockBytes += entry.Value
+	}
+
+	return sample
+}
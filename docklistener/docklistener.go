@@ -0,0 +1,58 @@
+// Package docklistener watches a container runtime (via the connector
+// package) for lifecycle events and resource usage, and republishes both
+// as channels that the view layer can render from. Init supervises the
+// connection itself (see supervisor.go); callers don't need to worry
+// about reconnecting.
+package docklistener
+
+import (
+	"time"
+
+	"github.com/byrnedo/dockdash/connector"
+	. "github.com/byrnedo/dockdash/logger"
+)
+
+const (
+	statsPollInterval = 2 * time.Second
+	chartWidth        = 50
+)
+
+// Chart is a fixed-width rolling window of sampled values, suitable for
+// feeding straight into a termui.Sparkline.
+type Chart struct {
+	Data []int
+}
+
+func newChart() *Chart {
+	return &Chart{Data: make([]int, 0, chartWidth)}
+}
+
+func (c *Chart) push(val int) {
+	c.Data = append(c.Data, val)
+	if len(c.Data) > chartWidth {
+		c.Data = c.Data[len(c.Data)-chartWidth:]
+	}
+}
+
+// StatsMsg carries the latest aggregated CPU/memory usage across every
+// currently running container, plus the per-container samples it was
+// built from so consumers can sort or rank individual containers
+// without a second round of polling.
+type StatsMsg struct {
+	CpuChart     *Chart
+	MemChart     *Chart
+	PerContainer map[string]*connector.StatsSample
+}
+
+// watchContainers does a one-shot full sync of the currently running
+// containers, used both on startup and after every reconnect.
+func watchContainers(conn connector.Connector, newContainerChan chan<- *connector.Container) {
+	containers, err := conn.List(false)
+	if err != nil {
+		Error.Println("Failed to list containers:", err)
+		return
+	}
+	for _, cont := range containers {
+		newContainerChan <- cont
+	}
+}
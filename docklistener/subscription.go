@@ -0,0 +1,116 @@
+package docklistener
+
+import (
+	"bufio"
+
+	"github.com/byrnedo/dockdash/connector"
+	. "github.com/byrnedo/dockdash/logger"
+)
+
+// logBufferLines is how many lines of log tail are kept for the detail
+// view's ring buffer.
+const logBufferLines = 200
+
+// ContainerStatsMsg is the per-container analogue of StatsMsg, used by
+// the single-container detail view.
+type ContainerStatsMsg struct {
+	ContainerID string
+	CpuChart    *Chart
+	MemChart    *Chart
+	NetChart    *Chart
+	BlockChart  *Chart
+}
+
+// ContainerSubscription streams logs and stats for a single container
+// until Stop is called. StatsChan receives a new sample on every tick;
+// LogChan receives the full tail buffer every time a new line arrives.
+type ContainerSubscription struct {
+	ID        string
+	StatsChan chan *ContainerStatsMsg
+	LogChan   chan []string
+
+	stopChan chan struct{}
+}
+
+// Subscribe starts streaming stats and logs for a single container. The
+// returned subscription must be stopped with Stop once the caller is
+// done (e.g. the detail view is closed), or the goroutines will leak.
+func Subscribe(conn connector.Connector, containerID string) *ContainerSubscription {
+	sub := &ContainerSubscription{
+		ID:        containerID,
+		StatsChan: make(chan *ContainerStatsMsg),
+		LogChan:   make(chan []string),
+		stopChan:  make(chan struct{}),
+	}
+	go sub.streamStats(conn)
+	go sub.streamLogs(conn)
+	return sub
+}
+
+// Stop tears down both the stats and log streams for this container.
+func (s *ContainerSubscription) Stop() {
+	close(s.stopChan)
+}
+
+func (s *ContainerSubscription) streamStats(conn connector.Connector) {
+	var (
+		cpuChart   = newChart()
+		memChart   = newChart()
+		netChart   = newChart()
+		blockChart = newChart()
+	)
+
+	samples, err := conn.Stats(s.ID, true, s.stopChan)
+	if err != nil {
+		Warning.Println("Stats stream for", s.ID, "failed to start:", err)
+		return
+	}
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case sample, ok := <-samples:
+			if !ok {
+				return
+			}
+			cpuChart.push(sample.CpuPercent)
+			memChart.push(sample.MemPercent)
+			netChart.push(int(sample.NetBytes))
+			blockChart.push(int(sample.BlockBytes))
+
+			select {
+			case s.StatsChan <- &ContainerStatsMsg{ContainerID: s.ID, CpuChart: cpuChart, MemChart: memChart, NetChart: netChart, BlockChart: blockChart}:
+			case <-s.stopChan:
+				return
+			}
+		}
+	}
+}
+
+func (s *ContainerSubscription) streamLogs(conn connector.Connector) {
+	reader, err := conn.Logs(s.ID, true, "200", s.stopChan)
+	if err != nil {
+		Warning.Println("Log stream for", s.ID, "failed to start:", err)
+		return
+	}
+	defer reader.Close()
+
+	lines := make([]string, 0, logBufferLines)
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > logBufferLines {
+			lines = lines[len(lines)-logBufferLines:]
+		}
+
+		buf := make([]string, len(lines))
+		copy(buf, lines)
+
+		select {
+		case s.LogChan <- buf:
+		case <-s.stopChan:
+			return
+		}
+	}
+}
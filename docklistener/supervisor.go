@@ -0,0 +1,149 @@
+package docklistener
+
+import (
+	"sync"
+	"time"
+
+	"github.com/byrnedo/dockdash/connector"
+	. "github.com/byrnedo/dockdash/logger"
+)
+
+const (
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+// ConnState is a connection-state transition reported to the UI so it
+// can show/hide the disconnected overlay.
+type ConnState int
+
+const (
+	Disconnected ConnState = iota
+	Connected
+)
+
+// ConnEvent carries a connection-state transition. Conn is set only on
+// Connected, letting callers that hold a reference to the backend (for
+// dispatching user actions) pick up the freshly (re)dialed connector.
+type ConnEvent struct {
+	State ConnState
+	Conn  connector.Connector
+}
+
+// Init starts the supervising loop: it dials via connect, and on any
+// failure (initial dial, or the event/stats streams dying mid-flight)
+// retries with exponential backoff, fully re-syncing container state
+// and re-subscribing to events each time it reconnects. connStateChan
+// receives a ConnEvent on every transition.
+func Init(connect func() (connector.Connector, error), newContainerChan chan<- *connector.Container, removeContainerChan chan<- string, statsChan chan<- *StatsMsg, connStateChan chan<- ConnEvent) {
+	go superviseLoop(connect, newContainerChan, removeContainerChan, statsChan, connStateChan)
+}
+
+func superviseLoop(connect func() (connector.Connector, error), newContainerChan chan<- *connector.Container, removeContainerChan chan<- string, statsChan chan<- *StatsMsg, connStateChan chan<- ConnEvent) {
+	backoff := initialBackoff
+	for {
+		conn, err := connect()
+		if err != nil {
+			Error.Println("Failed to connect:", err)
+			connStateChan <- ConnEvent{State: Disconnected}
+			time.Sleep(backoff)
+			backoff = nextBackoff(backoff)
+			continue
+		}
+
+		backoff = initialBackoff
+		connStateChan <- ConnEvent{State: Connected, Conn: conn}
+
+		lost := make(chan struct{})
+		signalLost := newOnceSignal(lost)
+
+		watchContainers(conn, newContainerChan)
+		go watchEventsSupervised(conn, newContainerChan, removeContainerChan, signalLost)
+		go watchStatsSupervised(conn, statsChan, signalLost)
+
+		<-lost
+		conn.Close()
+		connStateChan <- ConnEvent{State: Disconnected}
+	}
+}
+
+func nextBackoff(cur time.Duration) time.Duration {
+	next := cur * 2
+	if next > maxBackoff {
+		return maxBackoff
+	}
+	return next
+}
+
+// newOnceSignal returns a function that closes ch the first time it's
+// called and is a no-op thereafter, so the event and stats watchers can
+// both report a lost connection concurrently without a double-close
+// panic. sync.Once, not a bare bool, is what actually makes that safe
+// across goroutines.
+func newOnceSignal(ch chan struct{}) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(ch) })
+	}
+}
+
+func watchEventsSupervised(conn connector.Connector, newContainerChan chan<- *connector.Container, removeContainerChan chan<- string, lost func()) {
+	events, err := conn.Events()
+	if err != nil {
+		Error.Println("Failed to subscribe to events:", err)
+		lost()
+		return
+	}
+	for event := range events {
+		switch event.Type {
+		case connector.EventStart:
+			cont, err := conn.Inspect(event.ID)
+			if err != nil {
+				Error.Println("Failed to inspect container", event.ID, ":", err)
+				continue
+			}
+			newContainerChan <- cont
+		case connector.EventDie:
+			removeContainerChan <- event.ID
+		}
+	}
+	lost()
+}
+
+func watchStatsSupervised(conn connector.Connector, statsChan chan<- *StatsMsg, lost func()) {
+	var (
+		cpuChart = newChart()
+		memChart = newChart()
+		ticker   = time.NewTicker(statsPollInterval)
+	)
+	defer ticker.Stop()
+	for range ticker.C {
+		containers, err := conn.List(false)
+		if err != nil {
+			Error.Println("Failed to list containers for stats:", err)
+			lost()
+			return
+		}
+		var (
+			totalCpu, totalMem int
+			perContainer       = make(map[string]*connector.StatsSample, len(containers))
+		)
+		for _, cont := range containers {
+			samples, err := conn.Stats(cont.ID, false, nil)
+			if err != nil {
+				Warning.Println("Failed to sample stats for", cont.ID, ":", err)
+				continue
+			}
+			sample, ok := <-samples
+			if !ok || sample == nil {
+				continue
+			}
+			totalCpu += sample.CpuPercent
+			totalMem += sample.MemPercent
+			perContainer[cont.ID] = sample
+		}
+		cpuChart.push(totalCpu)
+		memChart.push(totalMem)
+		statsChan <- &StatsMsg{CpuChart: cpuChart, MemChart: memChart, PerContainer: perContainer}
+	}
+}
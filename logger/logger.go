@@ -0,0 +1,26 @@
+// Package logger provides a set of shared, leveled loggers used throughout
+// dockdash. Since the TUI owns the terminal, log output is normally
+// discarded and only written somewhere useful when a log file is
+// configured via InitLog.
+package logger
+
+import (
+	"io"
+	"log"
+)
+
+var (
+	Trace   *log.Logger
+	Info    *log.Logger
+	Warning *log.Logger
+	Error   *log.Logger
+)
+
+// InitLog wires up the package-level loggers to the given writers. Pass
+// ioutil.Discard for any level that should be silenced.
+func InitLog(traceHandle, infoHandle, warningHandle, errorHandle io.Writer) {
+	Trace = log.New(traceHandle, "TRACE: ", log.Ldate|log.Ltime|log.Lshortfile)
+	Info = log.New(infoHandle, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
+	Warning = log.New(warningHandle, "WARNING: ", log.Ldate|log.Ltime|log.Lshortfile)
+	Error = log.New(errorHandle, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
+}
@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/byrnedo/dockdash/config"
+	"github.com/byrnedo/dockdash/connector"
 	"github.com/byrnedo/dockdash/docklistener"
 	. "github.com/byrnedo/dockdash/logger"
+	"github.com/byrnedo/dockdash/metrics"
 	view "github.com/byrnedo/dockdash/view"
-	goDocker "github.com/fsouza/go-dockerclient"
 	ui "github.com/gizak/termui"
 	flag "github.com/ogier/pflag"
 )
@@ -25,15 +29,25 @@ type ContainersMsg struct {
 }
 
 var (
-	newContainerChan    chan *goDocker.Container
+	newContainerChan    chan *connector.Container
 	removeContainerChan chan string
 	doneChan            chan bool
 	uiEventChan         <-chan ui.Event
 	drawStatsChan       chan *docklistener.StatsMsg
+	uiView              *view.View
+
+	// latestMetricsSnapshot holds the most recent *metrics.Snapshot, set
+	// by uiRoutine on every stats tick and read by the metrics HTTP
+	// handler goroutine, which never shares uiRoutine's state directly.
+	latestMetricsSnapshot atomic.Value
 )
 
 var logFileFlag = flag.String("log-file", "", "Path to log file")
-var dockerEndpoint = flag.String("docker-endpoint", "unix:/var/run/docker.sock", "Docker connection endpoint")
+var backendFlag = flag.String("backend", "docker", "Container backend to use: docker or containerd")
+var endpointFlag = flag.String("endpoint", "unix:/var/run/docker.sock", "Backend connection endpoint (docker socket or containerd socket)")
+var configFileFlag = flag.String("config-file", "", "Path to config.toml (defaults to $XDG_CONFIG_HOME/dockdash/config.toml)")
+var writeConfigFlag = flag.Bool("write-config", false, "write the effective default config to the config file and exit")
+var metricsListenFlag = flag.String("metrics-listen", "", "Address to serve Prometheus container metrics on (e.g. :9111), disabled when empty")
 var helpFlag = flag.Bool("help", false, "help")
 var versionFlag = flag.Bool("version", false, "print version")
 
@@ -53,6 +67,13 @@ func init() {
 		fmt.Println(VERSION)
 		os.Exit(0)
 	}
+	if *writeConfigFlag {
+		if err := config.WriteDefault(*configFileFlag); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to write config:", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 }
 
 func main() {
@@ -67,9 +88,29 @@ func main() {
 		InitLog(ioutil.Discard, ioutil.Discard, ioutil.Discard, ioutil.Discard)
 	}
 
-	docker, err := goDocker.NewClient(*dockerEndpoint)
+	connect := func() (connector.Connector, error) {
+		return connector.New(*backendFlag, *endpointFlag)
+	}
+
+	cfg, err := config.Load(*configFileFlag)
 	if err != nil {
-		panic(err)
+		panic("Failed to load config:" + err.Error())
+	}
+
+	if err := view.ApplyTheme(view.Theme(cfg.Colors)); err != nil {
+		panic("Failed to apply theme:" + err.Error())
+	}
+
+	if err := validateKeyBindings(cfg.Keys); err != nil {
+		panic("Failed to load config:" + err.Error())
+	}
+
+	if len(*metricsListenFlag) > 0 {
+		collector := metrics.NewCollector(func() *metrics.Snapshot {
+			snap, _ := latestMetricsSnapshot.Load().(*metrics.Snapshot)
+			return snap
+		})
+		metrics.Serve(*metricsListenFlag, collector)
 	}
 
 	err = ui.Init()
@@ -79,67 +120,235 @@ func main() {
 
 	defer ui.Close()
 
-	var uiView = view.NewView()
+	uiView = view.NewView()
 
 	uiView.SetLayout()
 
 	uiView.Align()
 
-	newContainerChan = make(chan *goDocker.Container)
+	newContainerChan = make(chan *connector.Container)
 	removeContainerChan = make(chan string)
 	doneChan = make(chan bool)
 	uiEventChan = ui.EventCh()
 	drawStatsChan = make(chan *docklistener.StatsMsg)
+	connStateChan := make(chan docklistener.ConnEvent)
 
 	// Statistics
 
 	uiRoutine := func() {
 		var (
-			inspectMode   bool = false
+			inspectMode   bool = cfg.Toggles.StartInInspectMode
 			horizPosition int  = 0
 			offset        int  = 0
 			maxOffset     int  = 0
 			currentStats  *docklistener.StatsMsg
 			//lastStatsRender   time.Time = time.Time{}
-			currentContainers = make(map[string]*goDocker.Container)
-			ticker            = time.NewTicker(1 * time.Second)
+			controller      = view.NewContainerController()
+			ticker          = time.NewTicker(1 * time.Second)
+			pendingAction   func()
+			detailSub       *docklistener.ContainerSubscription
+			detailStatsChan chan *docklistener.ContainerStatsMsg
+			detailLogChan   chan []string
+			activeConn      connector.Connector
+			filterBuf       string
+			actionErrChan   = make(chan string)
+			statusUntil     time.Time
+
+			quitKey          = keyRune(cfg.Keys.Quit)
+			toggleInspectKey = keyRune(cfg.Keys.ToggleInspect)
+			cycleSortKey     = keyRune(cfg.Keys.CycleSort)
+			toggleAllKey     = keyRune(cfg.Keys.ToggleShowAll)
+			filterKey        = keyRune(cfg.Keys.Filter)
+			startKey         = keyRune(cfg.Keys.Start)
+			stopKey          = keyRune(cfg.Keys.Stop)
+			restartKey       = keyRune(cfg.Keys.Restart)
+			killKey          = keyRune(cfg.Keys.Kill)
+			pauseKey         = keyRune(cfg.Keys.Pause)
+			removeKey        = keyRune(cfg.Keys.Remove)
+			moveUpKey, _     = keyByName(cfg.Keys.MoveUp)
+			moveDownKey, _   = keyByName(cfg.Keys.MoveDown)
+			moveLeftKey, _   = keyByName(cfg.Keys.MoveLeft)
+			moveRightKey, _  = keyByName(cfg.Keys.MoveRight)
 		)
+		controller.SetSortMode(view.ParseSortMode(cfg.Toggles.SortMode))
+		controller.SetShowAll(cfg.Toggles.ShowStoppedContainers)
+		render := func() {
+			uiView.RenderContainers(controller, view.DockerInfoType(horizPosition), offset, inspectMode)
+		}
+		clampOffset := func() {
+			maxOffset = controller.Len() - 1
+			if offset > maxOffset {
+				offset = maxOffset
+			}
+			if offset < 0 {
+				offset = 0
+			}
+		}
+		// setStatus shows text in the info bar and holds it there for a
+		// few seconds, so the once-a-second Cons/CPU/Mem tick (below)
+		// doesn't stomp it before the user has had a chance to read it.
+		setStatus := func(text string) {
+			statusUntil = time.Now().Add(3 * time.Second)
+			uiView.SetStatus(text)
+		}
+		runAction := func(confirmMsg string, action func()) {
+			if activeConn == nil {
+				setStatus("Not connected")
+				return
+			}
+			if confirmMsg != "" {
+				pendingAction = action
+				uiView.ShowConfirm(confirmMsg)
+				return
+			}
+			go action()
+		}
+		enterDetail := func(cont *connector.Container) {
+			if activeConn == nil {
+				return
+			}
+			detailSub = docklistener.Subscribe(activeConn, cont.ID)
+			detailStatsChan = detailSub.StatsChan
+			detailLogChan = detailSub.LogChan
+			d := uiView.ShowDetail(cont.ID)
+			d.UpdateInspect(inspectLines(cont))
+		}
+		exitDetail := func() {
+			if detailSub != nil {
+				detailSub.Stop()
+			}
+			detailSub = nil
+			detailStatsChan = nil
+			detailLogChan = nil
+			uiView.HideDetail()
+		}
 		for {
 			select {
 			case e := <-uiEventChan:
 				Info.Println("Got ui event:", e)
 				if e.Type == ui.EventKey {
+					if uiView.ConfirmShowing() {
+						switch e.Ch {
+						case 'y':
+							uiView.HideConfirm()
+							action := pendingAction
+							pendingAction = nil
+							if action != nil {
+								go action()
+							}
+						case 'n':
+							uiView.HideConfirm()
+							pendingAction = nil
+							uiView.Render()
+						}
+						continue
+					}
+					if uiView.Detail != nil {
+						if e.Ch == quitKey || (e.Ch == 0 && (e.Key == ui.KeyCtrlC || e.Key == ui.KeyCtrlD)) {
+							doneChan <- true
+							continue
+						}
+						if e.Ch == 0 && e.Key == ui.KeyEsc {
+							exitDetail()
+						}
+						continue
+					}
+					if uiView.FilterInputShowing() {
+						switch {
+						case e.Ch != 0:
+							filterBuf += string(e.Ch)
+							uiView.UpdateFilterInput(filterBuf)
+						case e.Key == ui.KeyBackspace || e.Key == ui.KeyBackspace2:
+							if len(filterBuf) > 0 {
+								filterBuf = filterBuf[:len(filterBuf)-1]
+							}
+							uiView.UpdateFilterInput(filterBuf)
+						case e.Key == ui.KeyEnter:
+							if err := controller.SetFilter(filterBuf); err != nil {
+								setStatus(fmt.Sprintf("Bad filter: %s", err))
+							}
+							uiView.HideFilterInput()
+							clampOffset()
+							render()
+						case e.Key == ui.KeyEsc:
+							filterBuf = ""
+							uiView.HideFilterInput()
+						}
+						continue
+					}
 					switch e.Ch {
-					case 'q':
+					case quitKey:
 						doneChan <- true
-					case 'i':
+					case toggleInspectKey:
 						inspectMode = !inspectMode
-						uiView.RenderContainers(currentContainers, view.DockerInfoType(horizPosition), offset, inspectMode)
+						render()
+					case cycleSortKey:
+						mode := controller.CycleSort()
+						setStatus(fmt.Sprintf("Sorting by %s", mode))
+						render()
+					case toggleAllKey:
+						showAll := controller.ToggleShowAll()
+						if showAll && activeConn != nil {
+							go mergeAllContainers(activeConn)
+						}
+						clampOffset()
+						render()
+					case filterKey:
+						filterBuf = ""
+						uiView.ShowFilterInput(filterBuf)
+					case startKey:
+						if cont := uiView.SelectedContainer(controller, offset); cont != nil {
+							runAction("", func() { doAction(activeConn, cont.ID, connector.ActionStart, actionErrChan) })
+						}
+					case stopKey:
+						if cont := uiView.SelectedContainer(controller, offset); cont != nil {
+							runAction(fmt.Sprintf("Stop container %s?", cont.ID[:12]), func() { doAction(activeConn, cont.ID, connector.ActionStop, actionErrChan) })
+						}
+					case restartKey:
+						if cont := uiView.SelectedContainer(controller, offset); cont != nil {
+							runAction("", func() { doAction(activeConn, cont.ID, connector.ActionRestart, actionErrChan) })
+						}
+					case killKey:
+						if cont := uiView.SelectedContainer(controller, offset); cont != nil {
+							runAction(fmt.Sprintf("Kill container %s?", cont.ID[:12]), func() { doAction(activeConn, cont.ID, connector.ActionKill, actionErrChan) })
+						}
+					case pauseKey:
+						if cont := uiView.SelectedContainer(controller, offset); cont != nil {
+							runAction("", func() { doAction(activeConn, cont.ID, connector.ActionPause, actionErrChan) })
+						}
+					case removeKey:
+						if cont := uiView.SelectedContainer(controller, offset); cont != nil {
+							runAction(fmt.Sprintf("Remove container %s?", cont.ID[:12]), func() { doRemove(activeConn, cont.ID, actionErrChan) })
+						}
 					case 0:
 						switch e.Key {
 						case ui.KeyCtrlC, ui.KeyCtrlD:
 							doneChan <- true
-						case ui.KeyArrowLeft:
+						case ui.KeyEnter:
+							if cont := uiView.SelectedContainer(controller, offset); cont != nil {
+								enterDetail(cont)
+							}
+						case moveLeftKey:
 							if horizPosition > 0 {
 								horizPosition--
 							}
-							uiView.RenderContainers(currentContainers, view.DockerInfoType(horizPosition), offset, inspectMode)
-						case ui.KeyArrowRight:
+							render()
+						case moveRightKey:
 							if horizPosition < view.MaxHorizPosition {
 								horizPosition++
 							}
-							uiView.RenderContainers(currentContainers, view.DockerInfoType(horizPosition), offset, inspectMode)
-						case ui.KeyArrowDown:
+							render()
+						case moveDownKey:
 							if offset < maxOffset && offset < view.MaxContainers {
 								offset++
 							}
-							uiView.RenderContainers(currentContainers, view.DockerInfoType(horizPosition), offset, inspectMode)
+							render()
 							//shift the list down
-						case ui.KeyArrowUp:
+						case moveUpKey:
 							if offset > 0 {
 								offset--
 							}
-							uiView.RenderContainers(currentContainers, view.DockerInfoType(horizPosition), offset, inspectMode)
+							render()
 							//shift the list up
 						default:
 							Info.Printf("Got unhandled key %d\n", e.Key)
@@ -153,28 +362,63 @@ func main() {
 			case cont := <-newContainerChan:
 				Info.Println("Got new containers event")
 				Info.Printf("%d, %d, %d", offset, maxOffset, horizPosition)
-				currentContainers[cont.ID] = cont
-				maxOffset = len(currentContainers) - 1
-				uiView.RenderContainers(currentContainers, view.DockerInfoType(horizPosition), offset, inspectMode)
+				controller.Upsert(cont)
+				clampOffset()
+				render()
 
 			case removedContainerID := <-removeContainerChan:
-				maxOffset = len(currentContainers) - 1
-				if offset >= maxOffset {
-					offset = maxOffset
-				}
 				Info.Printf("%d, %d, %d", offset, maxOffset, horizPosition)
 				Info.Println("Got dead container event")
-				delete(currentContainers, removedContainerID)
-
-				uiView.RenderContainers(currentContainers, view.DockerInfoType(horizPosition), offset, inspectMode)
+				controller.Remove(removedContainerID)
+				clampOffset()
+				render()
 
 			case newStatsCharts := <-drawStatsChan:
 				currentStats = newStatsCharts
+				controller.SetStats(newStatsCharts.PerContainer)
+				latestMetricsSnapshot.Store(&metrics.Snapshot{
+					Containers: controller.Containers(),
+					Stats:      newStatsCharts.PerContainer,
+				})
 				uiView.RenderStats(newStatsCharts, offset)
+				render()
+
+			case detailStats := <-detailStatsChan:
+				if uiView.Detail != nil {
+					uiView.Detail.UpdateStats(detailStats)
+					uiView.Render()
+				}
+
+			case detailLogs := <-detailLogChan:
+				if uiView.Detail != nil {
+					uiView.Detail.UpdateLogs(detailLogs)
+					uiView.Render()
+				}
+
+			case connEvent := <-connStateChan:
+				switch connEvent.State {
+				case docklistener.Connected:
+					activeConn = connEvent.Conn
+					controller.Clear()
+					maxOffset = 0
+					uiView.HideError()
+					if controller.ShowAll() {
+						go mergeAllContainers(activeConn)
+					}
+				case docklistener.Disconnected:
+					activeConn = nil
+					uiView.ShowError("Lost connection to backend, reconnecting...")
+				}
+
+			case msg := <-actionErrChan:
+				setStatus(msg)
 
 			case <-ticker.C:
+				if time.Now().Before(statusUntil) {
+					continue
+				}
 				var (
-					numCons  = len(currentContainers)
+					numCons  = controller.Len()
 					totalCpu = 0
 					totalMem = 0
 				)
@@ -194,12 +438,64 @@ func main() {
 
 	go uiRoutine()
 
-	docklistener.Init(docker, newContainerChan, removeContainerChan, drawStatsChan)
+	docklistener.Init(connect, newContainerChan, removeContainerChan, drawStatsChan, connStateChan)
 
 	<-doneChan
 
 }
 
+// inspectLines renders a container's inspect data as JSON lines for the
+// detail view's scrollable pane.
+func inspectLines(cont *connector.Container) []string {
+	return strings.Split(string(cont.Raw), "\n")
+}
+
+// keyRune returns the first rune of a configured key string, or -1 (which
+// never matches a real keypress) for an empty binding.
+func keyRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return -1
+}
+
+// keyByName resolves a configured arrow-key binding to its termui key
+// constant.
+func keyByName(name string) (ui.Key, error) {
+	switch name {
+	case "Up":
+		return ui.KeyArrowUp, nil
+	case "Down":
+		return ui.KeyArrowDown, nil
+	case "Left":
+		return ui.KeyArrowLeft, nil
+	case "Right":
+		return ui.KeyArrowRight, nil
+	default:
+		return 0, fmt.Errorf("unknown arrow key %q (want one of Up, Down, Left, Right)", name)
+	}
+}
+
+// validateKeyBindings checks that the arrow-key bindings resolve to a real
+// key, the same way ApplyTheme validates color names, so a mistyped
+// config.toml fails fast at startup instead of leaving a key silently
+// unbound.
+func validateKeyBindings(k config.KeyBindings) error {
+	if _, err := keyByName(k.MoveUp); err != nil {
+		return fmt.Errorf("move_up: %s", err)
+	}
+	if _, err := keyByName(k.MoveDown); err != nil {
+		return fmt.Errorf("move_down: %s", err)
+	}
+	if _, err := keyByName(k.MoveLeft); err != nil {
+		return fmt.Errorf("move_left: %s", err)
+	}
+	if _, err := keyByName(k.MoveRight); err != nil {
+		return fmt.Errorf("move_right: %s", err)
+	}
+	return nil
+}
+
 func sum(nums ...int) int {
 	total := 0
 	for _, num := range nums {
@@ -207,3 +503,54 @@ func sum(nums ...int) int {
 	}
 	return total
 }
+
+// mergeAllContainers lists every container, including stopped ones, and
+// republishes each on newContainerChan so the running uiRoutine's
+// controller picks up the non-running entries the event stream alone
+// never reports.
+func mergeAllContainers(conn connector.Connector) {
+	containers, err := conn.List(true)
+	if err != nil {
+		Error.Println("Failed to list all containers:", err)
+		return
+	}
+	for _, cont := range containers {
+		newContainerChan <- cont
+	}
+}
+
+// refreshContainer re-inspects a container and republishes it on
+// newContainerChan so the running uiRoutine picks up its new state.
+func refreshContainer(conn connector.Connector, id string) {
+	cont, err := conn.Inspect(id)
+	if err != nil {
+		Error.Println("Failed to inspect container", id, ":", err)
+		return
+	}
+	newContainerChan <- cont
+}
+
+// doAction performs a non-destructive-to-list lifecycle action and
+// republishes the container's refreshed state on success. It's dispatched
+// on its own goroutine by runAction, since conn.Do can block for the
+// backend's full stop/restart grace period; errChan reports a failure
+// back to uiRoutine instead of touching uiView directly from off its
+// goroutine.
+func doAction(conn connector.Connector, id string, action connector.Action, errChan chan<- string) {
+	if err := conn.Do(id, action); err != nil {
+		errChan <- fmt.Sprintf("Failed to %s %s: %s", action, id[:12], err)
+		return
+	}
+	refreshContainer(conn, id)
+}
+
+// doRemove performs the remove action, which drops the container off
+// the list rather than refreshing it. Like doAction, it runs on its own
+// goroutine and reports failures back over errChan.
+func doRemove(conn connector.Connector, id string, errChan chan<- string) {
+	if err := conn.Do(id, connector.ActionRemove); err != nil {
+		errChan <- fmt.Sprintf("Failed to remove %s: %s", id[:12], err)
+		return
+	}
+	removeContainerChan <- id
+}
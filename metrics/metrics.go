@@ -0,0 +1,107 @@
+// Package metrics exposes the per-container resource usage docklistener
+// already collects as a Prometheus-compatible HTTP endpoint, so dockdash
+// can double as a lightweight exporter on hosts where installing
+// cAdvisor alongside it is overkill.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/byrnedo/dockdash/connector"
+	. "github.com/byrnedo/dockdash/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Snapshot is the latest per-container stats sample set, paired with the
+// container metadata needed to label them.
+type Snapshot struct {
+	Containers map[string]*connector.Container
+	Stats      map[string]*connector.StatsSample
+}
+
+var labelNames = []string{"id", "name", "image"}
+
+var (
+	cpuPercentDesc = prometheus.NewDesc(
+		"container_cpu_percent",
+		"Container CPU usage as a percentage of a single core.",
+		labelNames, nil,
+	)
+	memPercentDesc = prometheus.NewDesc(
+		"container_memory_percent",
+		"Container memory usage as a percentage of its limit.",
+		labelNames, nil,
+	)
+	// NetBytes and BlockBytes are each an rx+tx / read+write total, since
+	// that's all connector.StatsSample carries; they're exposed as
+	// single cumulative counters rather than split rx/tx series.
+	networkBytesTotalDesc = prometheus.NewDesc(
+		"container_network_bytes_total",
+		"Cumulative network bytes (received plus transmitted) since container start.",
+		labelNames, nil,
+	)
+	blockBytesTotalDesc = prometheus.NewDesc(
+		"container_block_io_bytes_total",
+		"Cumulative block device I/O bytes since container start.",
+		labelNames, nil,
+	)
+)
+
+// Collector implements prometheus.Collector by pulling from the latest
+// Snapshot returned by snapshot on every scrape.
+type Collector struct {
+	snapshot func() *Snapshot
+}
+
+// NewCollector returns a Collector backed by snapshot, which must be
+// safe to call from any goroutine.
+func NewCollector(snapshot func() *Snapshot) *Collector {
+	return &Collector{snapshot: snapshot}
+}
+
+// Describe reports the metrics this collector can produce.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cpuPercentDesc
+	ch <- memPercentDesc
+	ch <- networkBytesTotalDesc
+	ch <- blockBytesTotalDesc
+}
+
+// Collect emits one sample of each metric per container in the current
+// snapshot.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	snap := c.snapshot()
+	if snap == nil {
+		return
+	}
+	for id, sample := range snap.Stats {
+		cont, ok := snap.Containers[id]
+		if !ok {
+			continue
+		}
+		labels := []string{cont.ID, cont.Name, cont.Image}
+		ch <- prometheus.MustNewConstMetric(cpuPercentDesc, prometheus.GaugeValue, float64(sample.CpuPercent), labels...)
+		ch <- prometheus.MustNewConstMetric(memPercentDesc, prometheus.GaugeValue, float64(sample.MemPercent), labels...)
+		ch <- prometheus.MustNewConstMetric(networkBytesTotalDesc, prometheus.CounterValue, float64(sample.NetBytes), labels...)
+		ch <- prometheus.MustNewConstMetric(blockBytesTotalDesc, prometheus.CounterValue, float64(sample.BlockBytes), labels...)
+	}
+}
+
+// Serve registers collector against a dedicated registry and starts
+// serving it at addr's "/metrics" path in the background. Listener
+// failures are logged rather than fatal, since the exporter is an
+// optional side feature of dockdash.
+func Serve(addr string, collector *Collector) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			Error.Println("Metrics server failed:", err)
+		}
+	}()
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/byrnedo/dockdash/connector"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func collect(t *testing.T, c *Collector) []prometheus.Metric {
+	t.Helper()
+	ch := make(chan prometheus.Metric, 16)
+	c.Collect(ch)
+	close(ch)
+	var metrics []prometheus.Metric
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func TestCollectorEmitsNothingWithoutASnapshot(t *testing.T) {
+	c := NewCollector(func() *Snapshot { return nil })
+	if metrics := collect(t, c); len(metrics) != 0 {
+		t.Fatalf("expected no metrics with a nil snapshot, got %d", len(metrics))
+	}
+}
+
+func TestCollectorEmitsOneSampleOfEachMetricPerContainer(t *testing.T) {
+	snap := &Snapshot{
+		Containers: map[string]*connector.Container{
+			"abc": {ID: "abc", Name: "web", Image: "nginx"},
+		},
+		Stats: map[string]*connector.StatsSample{
+			"abc": {CpuPercent: 42, MemPercent: 17, NetBytes: 100, BlockBytes: 200},
+		},
+	}
+	c := NewCollector(func() *Snapshot { return snap })
+
+	metrics := collect(t, c)
+	if len(metrics) != 4 {
+		t.Fatalf("expected 4 metrics (cpu, mem, net, block), got %d", len(metrics))
+	}
+
+	for _, m := range metrics {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			t.Fatalf("failed to write metric: %s", err)
+		}
+		labels := map[string]string{}
+		for _, lp := range pb.GetLabel() {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		if labels["id"] != "abc" || labels["name"] != "web" || labels["image"] != "nginx" {
+			t.Fatalf("unexpected labels: %+v", labels)
+		}
+	}
+}
+
+func TestCollectorSkipsStatsWithoutAKnownContainer(t *testing.T) {
+	snap := &Snapshot{
+		Containers: map[string]*connector.Container{},
+		Stats: map[string]*connector.StatsSample{
+			"abc": {CpuPercent: 42},
+		},
+	}
+	c := NewCollector(func() *Snapshot { return snap })
+
+	if metrics := collect(t, c); len(metrics) != 0 {
+		t.Fatalf("expected stats for an unknown container to be skipped, got %d metrics", len(metrics))
+	}
+}
@@ -0,0 +1,272 @@
+package view
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/byrnedo/dockdash/connector"
+)
+
+// SortMode selects the key used to order the container list, cycled
+// with the 'o' key.
+type SortMode int
+
+const (
+	SortByName SortMode = iota
+	SortByCPU
+	SortByMem
+	SortByUptime
+	SortByStatus
+	SortByImage
+)
+
+// MaxSortMode is the highest valid SortMode value.
+const MaxSortMode = int(SortByImage)
+
+// ParseSortMode resolves a sort mode by its config/status-bar name (as
+// returned by SortMode.String), defaulting to SortByName for an unknown
+// or empty value.
+func ParseSortMode(name string) SortMode {
+	switch name {
+	case "cpu":
+		return SortByCPU
+	case "mem":
+		return SortByMem
+	case "uptime":
+		return SortByUptime
+	case "status":
+		return SortByStatus
+	case "image":
+		return SortByImage
+	default:
+		return SortByName
+	}
+}
+
+func (m SortMode) String() string {
+	switch m {
+	case SortByCPU:
+		return "cpu"
+	case SortByMem:
+		return "mem"
+	case SortByUptime:
+		return "uptime"
+	case SortByStatus:
+		return "status"
+	case SortByImage:
+		return "image"
+	default:
+		return "name"
+	}
+}
+
+// ContainerController owns the set of known containers plus the
+// sort/filter/show-all state used to turn them into the ordered rows
+// RenderContainers draws. It recomputes its ordered view only when the
+// container set or the sort/filter state actually changes, rather than
+// rescanning on every render.
+type ContainerController struct {
+	containers map[string]*connector.Container
+	stats      map[string]*connector.StatsSample
+
+	sortMode SortMode
+	filter   *regexp.Regexp
+	showAll  bool
+
+	ordered []string
+	dirty   bool
+}
+
+// NewContainerController returns an empty controller sorted by name with
+// no filter, showing only running containers.
+func NewContainerController() *ContainerController {
+	return &ContainerController{
+		containers: make(map[string]*connector.Container),
+		dirty:      true,
+	}
+}
+
+// Upsert adds or replaces a container's entry.
+func (c *ContainerController) Upsert(cont *connector.Container) {
+	c.containers[cont.ID] = cont
+	c.dirty = true
+}
+
+// Remove drops a container by ID.
+func (c *ContainerController) Remove(id string) {
+	delete(c.containers, id)
+	c.dirty = true
+}
+
+// Clear drops every tracked container, keeping the current sort/filter/
+// show-all settings. Used when the backend connection is re-established
+// and a full resync is about to happen.
+func (c *ContainerController) Clear() {
+	c.containers = make(map[string]*connector.Container)
+	c.dirty = true
+}
+
+// SetStats records the latest per-container stats sample set, used for
+// the CPU/Mem sort modes.
+func (c *ContainerController) SetStats(stats map[string]*connector.StatsSample) {
+	c.stats = stats
+	if c.sortMode == SortByCPU || c.sortMode == SortByMem {
+		c.dirty = true
+	}
+}
+
+// CycleSort advances to the next SortMode, wrapping around.
+func (c *ContainerController) CycleSort() SortMode {
+	c.sortMode = SortMode((int(c.sortMode) + 1) % (MaxSortMode + 1))
+	c.dirty = true
+	return c.sortMode
+}
+
+// SetSortMode sets the sort mode directly, used to apply a configured
+// startup default.
+func (c *ContainerController) SetSortMode(mode SortMode) {
+	c.sortMode = mode
+	c.dirty = true
+}
+
+// SetShowAll sets whether stopped containers are included, used to apply
+// a configured startup default.
+func (c *ContainerController) SetShowAll(showAll bool) {
+	c.showAll = showAll
+	c.dirty = true
+}
+
+// SetFilter compiles pattern as a regexp applied to name/image/ID. An
+// empty pattern clears the filter. An invalid pattern leaves the
+// previous filter in place and reports the error.
+func (c *ContainerController) SetFilter(pattern string) error {
+	if pattern == "" {
+		c.filter = nil
+		c.dirty = true
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	c.filter = re
+	c.dirty = true
+	return nil
+}
+
+// ToggleShowAll flips whether stopped containers are included, and
+// reports the new state.
+func (c *ContainerController) ToggleShowAll() bool {
+	c.showAll = !c.showAll
+	c.dirty = true
+	return c.showAll
+}
+
+// ShowAll reports whether stopped containers are currently included.
+func (c *ContainerController) ShowAll() bool {
+	return c.showAll
+}
+
+// SortMode reports the current sort mode.
+func (c *ContainerController) SortMode() SortMode {
+	return c.sortMode
+}
+
+// Len returns the number of rows the current filter/show-all state would
+// produce.
+func (c *ContainerController) Len() int {
+	return len(c.Ordered())
+}
+
+// Get returns the container with the given ID, or nil.
+func (c *ContainerController) Get(id string) *connector.Container {
+	return c.containers[id]
+}
+
+// Containers returns a copy of every tracked container keyed by ID,
+// safe to hand to another goroutine (e.g. the metrics exporter) without
+// racing future mutations of the controller's internal map.
+func (c *ContainerController) Containers() map[string]*connector.Container {
+	out := make(map[string]*connector.Container, len(c.containers))
+	for id, cont := range c.containers {
+		out[id] = cont
+	}
+	return out
+}
+
+// At returns the container at position i in the ordered view, or nil if
+// i is out of range.
+func (c *ContainerController) At(i int) *connector.Container {
+	ids := c.Ordered()
+	if i < 0 || i >= len(ids) {
+		return nil
+	}
+	return c.containers[ids[i]]
+}
+
+// Ordered returns the container IDs currently passing the filter and
+// show-all state, sorted by the current sort mode. The result is cached
+// until the next mutation.
+func (c *ContainerController) Ordered() []string {
+	if !c.dirty {
+		return c.ordered
+	}
+
+	ids := make([]string, 0, len(c.containers))
+	for id, cont := range c.containers {
+		if !c.showAll && cont.State != "running" {
+			continue
+		}
+		if c.filter != nil && !c.matches(cont) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return c.less(c.containers[ids[i]], c.containers[ids[j]])
+	})
+
+	c.ordered = ids
+	c.dirty = false
+	return ids
+}
+
+func (c *ContainerController) matches(cont *connector.Container) bool {
+	return c.filter.MatchString(cont.Name) ||
+		c.filter.MatchString(cont.Image) ||
+		c.filter.MatchString(cont.ID)
+}
+
+func (c *ContainerController) less(a, b *connector.Container) bool {
+	switch c.sortMode {
+	case SortByCPU:
+		return c.statFor(a.ID).CpuPercent > c.statFor(b.ID).CpuPercent
+	case SortByMem:
+		return c.statFor(a.ID).MemPercent > c.statFor(b.ID).MemPercent
+	case SortByUptime:
+		return a.Created > b.Created
+	case SortByStatus:
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		return a.ID < b.ID
+	case SortByImage:
+		if a.Image != b.Image {
+			return a.Image < b.Image
+		}
+		return a.ID < b.ID
+	default:
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	}
+}
+
+func (c *ContainerController) statFor(id string) *connector.StatsSample {
+	if s, ok := c.stats[id]; ok {
+		return s
+	}
+	return &connector.StatsSample{}
+}
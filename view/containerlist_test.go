@@ -0,0 +1,104 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/byrnedo/dockdash/connector"
+)
+
+func newTestContainer(id, name, image, status, created string) *connector.Container {
+	return &connector.Container{
+		ID:      id,
+		Name:    name,
+		Image:   image,
+		Status:  status,
+		State:   status,
+		Created: created,
+	}
+}
+
+func TestContainerControllerFiltersStoppedByDefault(t *testing.T) {
+	c := NewContainerController()
+	c.Upsert(newTestContainer("1", "web", "nginx", "running", "1"))
+	c.Upsert(newTestContainer("2", "db", "postgres", "exited", "2"))
+
+	ids := c.Ordered()
+	if len(ids) != 1 || ids[0] != "1" {
+		t.Fatalf("expected only the running container, got %v", ids)
+	}
+
+	c.SetShowAll(true)
+	ids = c.Ordered()
+	if len(ids) != 2 {
+		t.Fatalf("expected both containers with show-all, got %v", ids)
+	}
+}
+
+func TestContainerControllerSortByName(t *testing.T) {
+	c := NewContainerController()
+	c.SetShowAll(true)
+	c.Upsert(newTestContainer("1", "web", "nginx", "running", "1"))
+	c.Upsert(newTestContainer("2", "api", "golang", "running", "2"))
+
+	ids := c.Ordered()
+	if len(ids) != 2 || ids[0] != "2" || ids[1] != "1" {
+		t.Fatalf("expected [2 1] (api before web), got %v", ids)
+	}
+}
+
+func TestContainerControllerSortByCPU(t *testing.T) {
+	c := NewContainerController()
+	c.SetShowAll(true)
+	c.Upsert(newTestContainer("1", "web", "nginx", "running", "1"))
+	c.Upsert(newTestContainer("2", "api", "golang", "running", "2"))
+	c.SetStats(map[string]*connector.StatsSample{
+		"1": {CpuPercent: 10},
+		"2": {CpuPercent: 90},
+	})
+	c.SetSortMode(SortByCPU)
+
+	ids := c.Ordered()
+	if len(ids) != 2 || ids[0] != "2" || ids[1] != "1" {
+		t.Fatalf("expected [2 1] (highest CPU first), got %v", ids)
+	}
+}
+
+func TestContainerControllerFilterByName(t *testing.T) {
+	c := NewContainerController()
+	c.SetShowAll(true)
+	c.Upsert(newTestContainer("1", "web", "nginx", "running", "1"))
+	c.Upsert(newTestContainer("2", "api", "golang", "running", "2"))
+
+	if err := c.SetFilter("^web$"); err != nil {
+		t.Fatalf("SetFilter returned error: %s", err)
+	}
+	ids := c.Ordered()
+	if len(ids) != 1 || ids[0] != "1" {
+		t.Fatalf("expected only the matching container, got %v", ids)
+	}
+
+	if err := c.SetFilter("("); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+	ids = c.Ordered()
+	if len(ids) != 1 || ids[0] != "1" {
+		t.Fatalf("expected previous filter to remain in place, got %v", ids)
+	}
+}
+
+func TestContainerControllerOrderedIsCachedUntilDirty(t *testing.T) {
+	c := NewContainerController()
+	c.SetShowAll(true)
+	c.Upsert(newTestContainer("1", "web", "nginx", "running", "1"))
+
+	first := c.Ordered()
+	second := c.Ordered()
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("expected one container in each call, got %v and %v", first, second)
+	}
+
+	c.Remove("1")
+	if got := c.Ordered(); len(got) != 0 {
+		t.Fatalf("expected removal to invalidate the cache, got %v", got)
+	}
+}
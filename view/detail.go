@@ -0,0 +1,116 @@
+package view
+
+import (
+	"github.com/byrnedo/dockdash/docklistener"
+	ui "github.com/gizak/termui"
+)
+
+// ContainerDetailView is the "drill-in" screen for a single container:
+// its inspect JSON, a tailing log pane, and resource sparklines. It
+// replaces the main layout for as long as it's open.
+type ContainerDetailView struct {
+	ContainerID string
+
+	Inspect *ui.List
+	Logs    *ui.List
+
+	CpuChart   *ui.Sparkline
+	MemChart   *ui.Sparkline
+	NetChart   *ui.Sparkline
+	BlockChart *ui.Sparkline
+	charts     *ui.Sparklines
+
+	savedRows []*ui.Row
+}
+
+func newContainerDetailView(containerID string) *ContainerDetailView {
+	d := &ContainerDetailView{ContainerID: containerID}
+
+	d.Inspect = ui.NewList()
+	d.Inspect.Border = true
+	d.Inspect.BorderLabel = "Inspect: " + shortID(containerID)
+	d.Inspect.BorderFg = borderColor
+	d.Inspect.BorderLabelFg = headerColor
+
+	d.Logs = ui.NewList()
+	d.Logs.Border = true
+	d.Logs.BorderLabel = "Logs"
+	d.Logs.BorderFg = borderColor
+	d.Logs.BorderLabelFg = headerColor
+
+	d.CpuChart = ui.NewSparkline()
+	d.CpuChart.Title = "Cpu"
+	d.CpuChart.LineColor = cpuChartColor
+	d.MemChart = ui.NewSparkline()
+	d.MemChart.Title = "Mem"
+	d.MemChart.LineColor = memChartColor
+	d.NetChart = ui.NewSparkline()
+	d.NetChart.Title = "Net"
+	d.BlockChart = ui.NewSparkline()
+	d.BlockChart.Title = "Block IO"
+
+	d.charts = ui.NewSparklines(d.CpuChart, d.MemChart, d.NetChart, d.BlockChart)
+	d.charts.Height = 12
+	d.charts.Border = true
+	d.charts.BorderFg = borderColor
+	d.charts.BorderLabelFg = headerColor
+
+	return d
+}
+
+// ShowDetail swaps the layout to a ContainerDetailView for containerID,
+// remembering the current rows so HideDetail can restore them.
+func (v *View) ShowDetail(containerID string) *ContainerDetailView {
+	d := newContainerDetailView(containerID)
+	v.Detail = d
+
+	d.savedRows = ui.Body.Rows
+	ui.Body.Rows = nil
+	ui.Body.AddRows(
+		ui.NewRow(
+			ui.NewCol(6, 0, d.Inspect),
+			ui.NewCol(6, 0, d.Logs),
+		),
+		ui.NewRow(
+			ui.NewCol(12, 0, d.charts),
+		),
+	)
+	v.Align()
+	v.Render()
+	return d
+}
+
+// HideDetail tears down the detail layout and restores the container
+// list layout that was active before ShowDetail.
+func (v *View) HideDetail() {
+	if v.Detail == nil {
+		return
+	}
+	ui.Body.Rows = v.Detail.savedRows
+	v.Detail = nil
+	v.Align()
+	v.Render()
+}
+
+// UpdateInspect replaces the inspect pane with freshly formatted JSON
+// lines.
+func (d *ContainerDetailView) UpdateInspect(lines []string) {
+	d.Inspect.Items = lines
+}
+
+// UpdateLogs replaces the log pane with the latest tail buffer.
+func (d *ContainerDetailView) UpdateLogs(lines []string) {
+	d.Logs.Items = lines
+}
+
+// UpdateStats pushes a new per-container stats sample into the
+// sparklines.
+func (d *ContainerDetailView) UpdateStats(stats *docklistener.ContainerStatsMsg) {
+	if stats == nil {
+		return
+	}
+	d.CpuChart.Data = stats.CpuChart.Data
+	d.MemChart.Data = stats.MemChart.Data
+	d.NetChart.Data = stats.NetChart.Data
+	d.BlockChart.Data = stats.BlockChart.Data
+}
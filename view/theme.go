@@ -0,0 +1,76 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	ui "github.com/gizak/termui"
+)
+
+// Theme is the set of named colors used across the container list,
+// sparklines, and overlays. It mirrors config.Colors without this
+// package needing to import the config package.
+type Theme struct {
+	Border    string
+	Header    string
+	Selection string
+	CpuChart  string
+	MemChart  string
+}
+
+// ApplyTheme resolves each named color and overrides this package's
+// widget styling. Call it before NewView so the new widgets pick up the
+// resolved colors.
+func ApplyTheme(t Theme) error {
+	border, err := colorByName(t.Border)
+	if err != nil {
+		return fmt.Errorf("border color: %s", err)
+	}
+	header, err := colorByName(t.Header)
+	if err != nil {
+		return fmt.Errorf("header color: %s", err)
+	}
+	selection, err := colorByName(t.Selection)
+	if err != nil {
+		return fmt.Errorf("selection color: %s", err)
+	}
+	cpu, err := colorByName(t.CpuChart)
+	if err != nil {
+		return fmt.Errorf("cpu chart color: %s", err)
+	}
+	mem, err := colorByName(t.MemChart)
+	if err != nil {
+		return fmt.Errorf("mem chart color: %s", err)
+	}
+
+	borderColor = border
+	headerColor = header
+	selectionColor = selection
+	selectionColorName = strings.ToLower(t.Selection)
+	cpuChartColor = cpu
+	memChartColor = mem
+	return nil
+}
+
+func colorByName(name string) (ui.Color, error) {
+	switch strings.ToLower(name) {
+	case "black":
+		return ui.ColorBlack, nil
+	case "red":
+		return ui.ColorRed, nil
+	case "green":
+		return ui.ColorGreen, nil
+	case "yellow":
+		return ui.ColorYellow, nil
+	case "blue":
+		return ui.ColorBlue, nil
+	case "magenta":
+		return ui.ColorMagenta, nil
+	case "cyan":
+		return ui.ColorCyan, nil
+	case "white":
+		return ui.ColorWhite, nil
+	default:
+		return 0, fmt.Errorf("unknown color %q (want one of black, red, green, yellow, blue, magenta, cyan, white)", name)
+	}
+}
@@ -0,0 +1,312 @@
+// Package view owns everything that ends up on screen: the termui layout,
+// the container list widget, and the aggregate stats sparklines. main.go
+// feeds it data; it never talks to Docker directly.
+package view
+
+import (
+	"fmt"
+
+	"github.com/byrnedo/dockdash/connector"
+	"github.com/byrnedo/dockdash/docklistener"
+	ui "github.com/gizak/termui"
+)
+
+// DockerInfoType selects which column of per-container info is shown in
+// the wide info column, cycled with the left/right arrow keys.
+type DockerInfoType int
+
+const (
+	DockerPorts DockerInfoType = iota
+	DockerCommand
+	DockerCreated
+)
+
+// MaxHorizPosition is the highest valid DockerInfoType value.
+const MaxHorizPosition = int(DockerCreated)
+
+// MaxContainers bounds how far down the list the cursor can move. Every
+// row is still rendered; rows past this bound cannot be selected.
+const MaxContainers = 200
+
+var (
+	borderColor = ui.ColorCyan
+	headerColor = ui.ColorWhite
+	// selectionColor/selectionColorName are kept in lockstep: the ui.Color
+	// drives widget styling, the name drives termui markup strings (e.g.
+	// RenderContainers' row highlight), which take a color name rather
+	// than a ui.Color value.
+	selectionColor     = ui.ColorYellow
+	selectionColorName = "yellow"
+	cpuChartColor      = ui.ColorGreen
+	memChartColor      = ui.ColorMagenta
+)
+
+// View holds every termui widget dockdash draws.
+type View struct {
+	ContainerList *ui.List
+	InfoBar       *ui.Par
+	CpuChart      *ui.Sparkline
+	MemChart      *ui.Sparkline
+	CpuGroup      *ui.Sparklines
+	MemGroup      *ui.Sparklines
+
+	// Modal is non-nil while a confirmation dialog is overlaid on the
+	// rest of the layout. Render draws it on top when set.
+	Modal *ui.Par
+
+	// ErrorView is non-nil while the backend connection is down. Render
+	// draws it on top when set, and it takes a back seat to Modal if
+	// both are somehow showing at once.
+	ErrorView *ui.Par
+
+	// Detail is non-nil while the single-container drill-in view
+	// (ShowDetail/HideDetail) is replacing the main layout.
+	Detail *ContainerDetailView
+
+	// FilterInput is non-nil while the '/' command line is accepting a
+	// filter pattern. Render draws it in place of the info bar when set.
+	FilterInput *ui.Par
+
+	orderedIDs []string
+}
+
+// NewView constructs the widgets with their static styling. Call
+// SetLayout/Align before the first Render.
+func NewView() *View {
+	v := &View{}
+
+	v.ContainerList = ui.NewList()
+	v.ContainerList.Border = true
+	v.ContainerList.BorderLabel = "Containers"
+	v.ContainerList.BorderFg = borderColor
+	v.ContainerList.BorderLabelFg = headerColor
+	v.ContainerList.Height = ui.TermHeight() - 4
+
+	v.InfoBar = ui.NewPar("")
+	v.InfoBar.Border = true
+	v.InfoBar.BorderLabel = "Info"
+	v.InfoBar.BorderFg = borderColor
+	v.InfoBar.BorderLabelFg = headerColor
+	v.InfoBar.Height = 3
+
+	v.CpuChart = ui.NewSparkline()
+	v.CpuChart.Title = "Cpu"
+	v.CpuChart.LineColor = cpuChartColor
+	v.CpuGroup = ui.NewSparklines(v.CpuChart)
+	v.CpuGroup.Height = 4
+	v.CpuGroup.Border = true
+	v.CpuGroup.BorderFg = borderColor
+	v.CpuGroup.BorderLabelFg = headerColor
+
+	v.MemChart = ui.NewSparkline()
+	v.MemChart.Title = "Mem"
+	v.MemChart.LineColor = memChartColor
+	v.MemGroup = ui.NewSparklines(v.MemChart)
+	v.MemGroup.Height = 4
+	v.MemGroup.Border = true
+	v.MemGroup.BorderFg = borderColor
+	v.MemGroup.BorderLabelFg = headerColor
+
+	return v
+}
+
+// SetLayout arranges the widgets in ui.Body.
+func (v *View) SetLayout() {
+	ui.Body.AddRows(
+		ui.NewRow(
+			ui.NewCol(12, 0, v.ContainerList),
+		),
+		ui.NewRow(
+			ui.NewCol(6, 0, v.CpuGroup),
+			ui.NewCol(6, 0, v.MemGroup),
+		),
+		ui.NewRow(
+			ui.NewCol(12, 0, v.InfoBar),
+		),
+	)
+}
+
+// Align recomputes widget positions after SetLayout or a resize.
+func (v *View) Align() {
+	ui.Body.Align()
+}
+
+// ResetSize re-reads the terminal dimensions and realigns.
+func (v *View) ResetSize() {
+	ui.Body.Width = ui.TermWidth()
+	v.ContainerList.Height = ui.TermHeight() - 4
+	v.Align()
+}
+
+// Render flushes the current widget state to the terminal, drawing the
+// confirmation modal or disconnected overlay on top when either is
+// showing.
+func (v *View) Render() {
+	if v.Modal != nil {
+		ui.Render(ui.Body, v.Modal)
+		return
+	}
+	if v.ErrorView != nil {
+		ui.Render(ui.Body, v.ErrorView)
+		return
+	}
+	if v.FilterInput != nil {
+		ui.Render(ui.Body, v.FilterInput)
+		return
+	}
+	ui.Render(ui.Body)
+}
+
+// ShowFilterInput overlays a bottom input line pre-filled with text,
+// used to edit a filter pattern in place.
+func (v *View) ShowFilterInput(text string) {
+	in := ui.NewPar("/" + text)
+	in.Height = 3
+	in.Width = ui.TermWidth()
+	in.Border = true
+	in.BorderLabel = "Filter (Enter to apply, Esc to cancel)"
+	in.BorderFg = borderColor
+	in.BorderLabelFg = headerColor
+	in.X = 0
+	in.Y = ui.TermHeight() - in.Height
+	v.FilterInput = in
+	v.Render()
+}
+
+// UpdateFilterInput redraws the filter input line with the given text.
+func (v *View) UpdateFilterInput(text string) {
+	if v.FilterInput == nil {
+		return
+	}
+	v.FilterInput.Text = "/" + text
+	v.Render()
+}
+
+// HideFilterInput clears the filter input line.
+func (v *View) HideFilterInput() {
+	v.FilterInput = nil
+	v.Render()
+}
+
+// FilterInputShowing reports whether the filter input line is currently
+// up.
+func (v *View) FilterInputShowing() bool {
+	return v.FilterInput != nil
+}
+
+// ShowError overlays a centered, red-bordered message reporting that
+// the backend connection is down.
+func (v *View) ShowError(text string) {
+	errPar := ui.NewPar(text)
+	errPar.Height = 5
+	errPar.Width = len(text) + 10
+	if errPar.Width < 30 {
+		errPar.Width = 30
+	}
+	errPar.Border = true
+	errPar.BorderLabel = "Disconnected"
+	errPar.BorderFg = ui.ColorRed
+	errPar.TextFgColor = ui.ColorRed
+	errPar.X = (ui.TermWidth() - errPar.Width) / 2
+	errPar.Y = (ui.TermHeight() - errPar.Height) / 2
+	v.ErrorView = errPar
+	v.Render()
+}
+
+// HideError clears the disconnected overlay.
+func (v *View) HideError() {
+	v.ErrorView = nil
+	v.Render()
+}
+
+// SelectedContainer returns the container under the cursor at offset,
+// using the ordering established by the last RenderContainers call.
+func (v *View) SelectedContainer(controller *ContainerController, offset int) *connector.Container {
+	if offset < 0 || offset >= len(v.orderedIDs) {
+		return nil
+	}
+	return controller.Get(v.orderedIDs[offset])
+}
+
+// ShowConfirm overlays a centered confirmation dialog asking the user to
+// press y/n before a destructive action proceeds.
+func (v *View) ShowConfirm(prompt string) {
+	modal := ui.NewPar(prompt + "\n\n[y]es / [n]o")
+	modal.Height = 5
+	modal.Width = len(prompt) + 10
+	modal.Border = true
+	modal.BorderLabel = "Confirm"
+	modal.BorderFg = ui.ColorRed
+	modal.TextFgColor = ui.ColorWhite
+	modal.X = (ui.TermWidth() - modal.Width) / 2
+	modal.Y = (ui.TermHeight() - modal.Height) / 2
+	v.Modal = modal
+	v.Render()
+}
+
+// HideConfirm clears the confirmation dialog.
+func (v *View) HideConfirm() {
+	v.Modal = nil
+}
+
+// ConfirmShowing reports whether a confirmation dialog is currently up.
+func (v *View) ConfirmShowing() bool {
+	return v.Modal != nil
+}
+
+// SetStatus writes a message to the info bar, used to surface action
+// failures without panicking.
+func (v *View) SetStatus(text string) {
+	v.InfoBar.Text = text
+	v.Render()
+}
+
+// RenderContainers redraws the container list: id/name, the
+// DockerInfoType-selected column and the state, highlighting the row at offset. The
+// controller supplies the already-sorted/filtered ordering.
+func (v *View) RenderContainers(controller *ContainerController, infoType DockerInfoType, offset int, inspectMode bool) {
+	ids := controller.Ordered()
+	v.orderedIDs = ids
+
+	rows := make([]string, 0, len(ids))
+	for i, id := range ids {
+		cont := controller.Get(id)
+		row := fmt.Sprintf("%-12s  %-20s  %-20s  %s", shortID(id), cont.Name, extraColumn(cont, infoType), cont.State)
+		if i == offset {
+			row = fmt.Sprintf("[%s](fg-black,bg-%s)", row, selectionColorName)
+		}
+		rows = append(rows, row)
+	}
+	v.ContainerList.BorderLabel = fmt.Sprintf("Containers (sort: %s)", controller.SortMode())
+	v.ContainerList.Items = rows
+	v.Render()
+}
+
+// RenderStats pushes the latest aggregate stats sample into the
+// sparklines.
+func (v *View) RenderStats(stats *docklistener.StatsMsg, offset int) {
+	if stats == nil {
+		return
+	}
+	v.CpuChart.Data = stats.CpuChart.Data
+	v.MemChart.Data = stats.MemChart.Data
+	v.Render()
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func extraColumn(cont *connector.Container, infoType DockerInfoType) string {
+	switch infoType {
+	case DockerCommand:
+		return cont.Command
+	case DockerCreated:
+		return cont.Created
+	default:
+		return cont.Ports
+	}
+}